@@ -0,0 +1,89 @@
+package common
+
+import (
+	"crypto/tls"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/paulfdunn/rest-app-common/config"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// acmeStagingDirectoryURL is the ACME directory endpoint used when cnfg.ACME.Staging is true.
+// It issues certificates that are not trusted by browsers, but is not subject to the
+// production rate limits; use it while testing a new domain/config.
+const acmeStagingDirectoryURL = "https://acme-staging-v02.api.letsencrypt.org/directory"
+
+// acmeRenewalCheckInterval is how often the cached certificates are inspected so renewal
+// events can be written to the audit log.
+const acmeRenewalCheckInterval = 1 * time.Hour
+
+// acmeCacheDirName is the PersistentDirectory subdirectory autocert uses to persist
+// certificates across restarts.
+const acmeCacheDirName = "acme-cache"
+
+// acmePrepareTLS configures server to serve certificates obtained and renewed automatically via
+// autocert.Manager; the caller still makes the blocking server.ListenAndServeTLS call itself.
+// When the configured challenge type requires it, the HTTP-01 challenge is served over its own
+// *http.Server, multiplexed alongside mux on the standard HTTP port, which this function starts
+// and returns so the caller can shut it down alongside server; TLS-ALPN-01 is handled directly
+// within the TLS handshake and needs no separate listener, so acmePrepareTLS returns nil for it.
+func acmePrepareTLS(cnfg config.Config, server *http.Server, mux *http.ServeMux) *http.Server {
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cnfg.ACME.Domains...),
+		Cache:      autocert.DirCache(filepath.Join(*cnfg.PersistentDirectory, acmeCacheDirName)),
+		Email:      cnfg.ACME.ContactEmail,
+	}
+	if cnfg.ACME.Staging {
+		manager.Client = &acme.Client{DirectoryURL: acmeStagingDirectoryURL}
+	}
+
+	server.TLSConfig = manager.TLSConfig()
+
+	var challengeServer *http.Server
+	if cnfg.ACME.ChallengeType != config.ACMEChallengeTLSALPN01 {
+		challengeServer = &http.Server{Addr: ":http", Handler: manager.HTTPHandler(mux)}
+		go func() {
+			if err := challengeServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				config.AppLogger().Printf(config.Error, "ACME HTTP-01 challenge handler error: %v", err)
+			}
+		}()
+	}
+
+	go acmeLogRenewals(cnfg, manager)
+
+	config.AuditLogger().With("component", "acme").Printf(config.Audit,
+		"ACME: provisioning TLS certificates for domains:%v", cnfg.ACME.Domains)
+
+	return challengeServer
+}
+
+// acmeLogRenewals periodically inspects the cached certificates so certificate lifecycle
+// events (issuance and renewal) are written to the audit log the same way other user actions
+// are. autocert.Manager itself has no renewal-event hook; this infers renewal by tracking each
+// domain's certificate expiration time as it changes across checks.
+func acmeLogRenewals(cnfg config.Config, manager *autocert.Manager) {
+	seenExpiry := map[string]time.Time{}
+	ticker := time.NewTicker(acmeRenewalCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for _, domain := range cnfg.ACME.Domains {
+			cert, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: domain})
+			if err != nil || cert == nil || len(cert.Certificate) == 0 {
+				continue
+			}
+			leaf := cert.Leaf
+			if leaf == nil {
+				continue
+			}
+			if prior, ok := seenExpiry[domain]; !ok || !prior.Equal(leaf.NotAfter) {
+				seenExpiry[domain] = leaf.NotAfter
+				config.AuditLogger().With("component", "acme", "domain", domain).Printf(config.Audit,
+					"ACME: certificate for domain:%s valid until:%v", domain, leaf.NotAfter)
+			}
+		}
+	}
+}
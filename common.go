@@ -6,8 +6,7 @@ import (
 	"time"
 
 	"github.com/paulfdunn/authJWT"
-	"github.com/paulfdunn/logh"
-	"github.com/paulfdunn/rest-app/common/config"
+	"github.com/paulfdunn/rest-app-common/config"
 )
 
 const (
@@ -18,10 +17,11 @@ const (
 )
 
 // ConfigInit initializes the configuration. It is separate from OtherInit as some configuration
-// may be required prior to calling other Init functions.
-func ConfigInit(cnfg config.Config, filepathsToDeleteOnReset []string) {
+// may be required prior to calling other Init functions. customLogger is the Logger config and
+// common will log through internally; pass nil to use the default, config.LoghLogger.
+func ConfigInit(cnfg config.Config, filepathsToDeleteOnReset []string, customLogger config.Logger) {
 	config.Init(cnfg, checkLogSize, maxLogSize, checkLogSizeAudit, maxLogSizeAudit,
-		filepathsToDeleteOnReset)
+		filepathsToDeleteOnReset, customLogger)
 }
 
 // OtherInit calls all required Init functions.
@@ -30,17 +30,43 @@ func OtherInit(authConfig authJWT.Config, mux *http.ServeMux) {
 	authJWT.Init(authConfig, mux)
 }
 
-// ListenAndServeTLS IS A BLOCKING FUNCTION that starts the HTTP server.
-func ListenAndServeTLS(logName string, mux *http.ServeMux, port string, readTimeout time.Duration, writeTimeout time.Duration,
+// ListenAndServeTLS IS A BLOCKING FUNCTION that starts the HTTP server. If certFilepath and
+// keyFilepath are both empty and cnfg.ACME is populated, certificates are instead obtained and
+// auto-renewed via ACME; see acmePrepareTLS. Callers that need graceful shutdown on
+// SIGINT/SIGTERM (e.g. when running under systemd or a container orchestrator) should use Run
+// instead.
+func ListenAndServeTLS(cnfg config.Config, mux *http.ServeMux, port string, readTimeout time.Duration, writeTimeout time.Duration,
 	certFilepath string, keyFilepath string) {
-	server := &http.Server{
+	server := newHTTPServer(mux, port, readTimeout, writeTimeout)
+	prepareTLS(cnfg, server, mux, certFilepath, keyFilepath)
+	serveTLS(cnfg, server, certFilepath, keyFilepath)
+}
+
+// newHTTPServer builds the *http.Server shared by ListenAndServeTLS and Run.
+func newHTTPServer(mux *http.ServeMux, port string, readTimeout time.Duration, writeTimeout time.Duration) *http.Server {
+	return &http.Server{
 		Addr:           port,
 		Handler:        mux,
 		ReadTimeout:    readTimeout,
 		WriteTimeout:   writeTimeout,
 		MaxHeaderBytes: 1 << 20,
 	}
+}
+
+// prepareTLS configures server for TLS, including provisioning certificates via ACME when
+// certFilepath and keyFilepath are both empty and cnfg.ACME is populated. It returns the ACME
+// HTTP-01 challenge server, if one was started, so callers needing graceful shutdown (Run) can
+// stop it alongside server; it returns nil when not using ACME, or when the configured
+// challenge type needs no separate listener. Call this before serveTLS.
+func prepareTLS(cnfg config.Config, server *http.Server, mux *http.ServeMux, certFilepath string, keyFilepath string) *http.Server {
+	if certFilepath == "" && keyFilepath == "" && cnfg.ACME != nil {
+		return acmePrepareTLS(cnfg, server, mux)
+	}
+	return nil
+}
 
-	logh.Map[logName].Printf(logh.Error, "ListenAndServeTLS error: %v",
+// serveTLS blocks until server stops serving, logging the result. Call prepareTLS first.
+func serveTLS(cnfg config.Config, server *http.Server, certFilepath string, keyFilepath string) {
+	config.AppLogger().Printf(config.Error, "ListenAndServeTLS error: %v",
 		server.ListenAndServeTLS(certFilepath, keyFilepath))
 }
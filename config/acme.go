@@ -0,0 +1,24 @@
+package config
+
+// ACMEConfig configures automatic TLS certificate provisioning and renewal via an ACME CA
+// (e.g. Let's Encrypt). See common.ListenAndServeTLS, which uses this to obtain certificates
+// via golang.org/x/crypto/acme/autocert when no static cert/key files are provided.
+type ACMEConfig struct {
+	// Domains are the host names the CA will be asked to issue certificates for.
+	Domains []string `json:",omitempty"`
+	// ContactEmail is provided to the CA for expiration and other account notices.
+	ContactEmail string `json:",omitempty"`
+	// Staging directs requests at the CA's staging endpoint, which issues untrusted
+	// certificates but is not subject to the CA's production rate limits. Use while testing.
+	Staging bool `json:",omitempty"`
+	// ChallengeType selects the ACME challenge used to prove domain ownership: "http-01"
+	// (the default, requiring port 80 be reachable) or "tls-alpn-01" (requiring port 443
+	// be reachable prior to having a certificate).
+	ChallengeType string `json:",omitempty"`
+}
+
+// ACME challenge type values for ACMEConfig.ChallengeType.
+const (
+	ACMEChallengeHTTP01    = "http-01"
+	ACMEChallengeTLSALPN01 = "tls-alpn-01"
+)
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/paulfdunn/osh/runtimeh"
+)
+
+// backupManifestName and backupKVSName are the entry names written into a Backup archive.
+const (
+	backupManifestName = "manifest.json"
+	backupKVSName      = "kvs.json"
+)
+
+// backupSchemaVersion identifies the layout of a Backup archive. Restore rejects any archive
+// whose manifest SchemaVersion does not match; there is no migration path yet, so an archive
+// from an incompatible version is refused rather than applied blindly.
+const backupSchemaVersion = 1
+
+// backupManifest is the first entry in every Backup archive.
+type backupManifest struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	AppName       string `json:"appName"`
+	CreatedUnix   int64  `json:"createdUnix"`
+}
+
+var (
+	backupFilepath  = flag.String("backup", "", "Fully qualified path to write a configuration backup archive to.")
+	restoreFilepath = flag.String("restore", "", "Fully qualified path to a configuration backup archive to restore from prior to starting.")
+)
+
+// Backup writes every KVS-persisted key, plus any files matched by the filepathsToBackup Glob
+// patterns, to w as a single tar+gzip archive. The archive begins with a manifest recording
+// backupSchemaVersion, appName, and the creation time, so Restore can validate it came from a
+// compatible instance of the same application.
+func Backup(w io.Writer, appName string, filepathsToBackup []string) error {
+	gzw := gzip.NewWriter(w)
+	defer gzw.Close()
+	tw := tar.NewWriter(gzw)
+	defer tw.Close()
+
+	manifest := backupManifest{SchemaVersion: backupSchemaVersion, AppName: appName, CreatedUnix: time.Now().Unix()}
+	mb, err := json.Marshal(manifest)
+	if err != nil {
+		return runtimeh.SourceInfoError("marshaling backup manifest", err)
+	}
+	if err := tarWriteBytes(tw, backupManifestName, mb); err != nil {
+		return runtimeh.SourceInfoError("writing backup manifest", err)
+	}
+
+	keys, err := kvi.Keys()
+	if err != nil {
+		return runtimeh.SourceInfoError("listing kvs keys", err)
+	}
+	kvEntries := map[string]json.RawMessage{}
+	for _, k := range keys {
+		b, err := kvi.Get(k)
+		if err != nil {
+			return runtimeh.SourceInfoError(fmt.Sprintf("getting kvs key %q", k), err)
+		}
+		kvEntries[k] = b
+	}
+	kvb, err := json.Marshal(kvEntries)
+	if err != nil {
+		return runtimeh.SourceInfoError("marshaling kvs entries", err)
+	}
+	if err := tarWriteBytes(tw, backupKVSName, kvb); err != nil {
+		return runtimeh.SourceInfoError("writing kvs entries", err)
+	}
+
+	for _, pattern := range filepathsToBackup {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return runtimeh.SourceInfoError(fmt.Sprintf("globbing %q", pattern), err)
+		}
+		for _, m := range matches {
+			if err := tarWriteFile(tw, m); err != nil {
+				return runtimeh.SourceInfoError(fmt.Sprintf("writing %q", m), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Restore reads a Backup archive from r and re-serializes its KVS entries, through
+// kvi.Serialize, into the current datastore, then writes back any backed up files to their
+// original paths. It refuses to run if the manifest's app name or schema version does not match,
+// if the current datastore already has entries and allowOverwrite is false, or if any archived
+// file entry falls outside the filepathsToBackup patterns (the same patterns passed to Backup),
+// which rejects a tampered or corrupted archive that tries to write outside that set (tar-slip).
+func Restore(r io.Reader, appName string, allowOverwrite bool, filepathsToBackup []string) error {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return runtimeh.SourceInfoError("opening backup gzip stream", err)
+	}
+	defer gzr.Close()
+	tr := tar.NewReader(gzr)
+
+	var manifest backupManifest
+	var haveManifest bool
+	kvEntries := map[string]json.RawMessage{}
+	files := map[string][]byte{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return runtimeh.SourceInfoError("reading backup archive", err)
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return runtimeh.SourceInfoError(fmt.Sprintf("reading backup entry %q", hdr.Name), err)
+		}
+		switch hdr.Name {
+		case backupManifestName:
+			if err := json.Unmarshal(b, &manifest); err != nil {
+				return runtimeh.SourceInfoError("parsing backup manifest", err)
+			}
+			haveManifest = true
+		case backupKVSName:
+			if err := json.Unmarshal(b, &kvEntries); err != nil {
+				return runtimeh.SourceInfoError("parsing backed up kvs entries", err)
+			}
+		default:
+			if !restorePathAllowed(hdr.Name, filepathsToBackup) {
+				return fmt.Errorf("backup entry %q is outside the allowed restore paths", hdr.Name)
+			}
+			files[hdr.Name] = b
+		}
+	}
+
+	if !haveManifest {
+		return fmt.Errorf("backup archive is missing %s", backupManifestName)
+	}
+	if manifest.AppName != appName {
+		return fmt.Errorf("backup app name %q does not match %q", manifest.AppName, appName)
+	}
+	if manifest.SchemaVersion != backupSchemaVersion {
+		return fmt.Errorf("backup schema version %d is not compatible with %d", manifest.SchemaVersion, backupSchemaVersion)
+	}
+
+	if !allowOverwrite {
+		keys, err := kvi.Keys()
+		if err != nil {
+			return runtimeh.SourceInfoError("listing kvs keys", err)
+		}
+		if len(keys) > 0 {
+			return fmt.Errorf("refusing to restore over an existing datastore; pass --reset to overwrite")
+		}
+	}
+
+	for k, v := range kvEntries {
+		var obj interface{}
+		if err := json.Unmarshal(v, &obj); err != nil {
+			return runtimeh.SourceInfoError(fmt.Sprintf("parsing backed up kvs entry %q", k), err)
+		}
+		if err := kvi.Serialize(k, obj); err != nil {
+			return runtimeh.SourceInfoError(fmt.Sprintf("restoring kvs entry %q", k), err)
+		}
+	}
+
+	for name, b := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return runtimeh.SourceInfoError(fmt.Sprintf("creating directory for %q", name), err)
+		}
+		if err := os.WriteFile(name, b, 0644); err != nil {
+			return runtimeh.SourceInfoError(fmt.Sprintf("writing %q", name), err)
+		}
+	}
+
+	return nil
+}
+
+// restorePathAllowed reports whether name, an archive entry's path as written by Backup, has no
+// ".." traversal and matches one of the filepathsToBackup Glob patterns. Entry names legitimately
+// include absolute paths, since Restore writes files back to the same paths Backup read them
+// from, so this is the tar-slip guard for Restore: an archive is untrusted input, so an entry
+// name is never used to write to disk without first checking it falls inside the set of paths
+// the application itself chose to back up, rather than trusting the archive's own contents.
+func restorePathAllowed(name string, filepathsToBackup []string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(filepath.Clean(name)), "/") {
+		if part == ".." {
+			return false
+		}
+	}
+	for _, pattern := range filepathsToBackup {
+		if ok, err := filepath.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// tarWriteBytes writes b into tw as a single-entry file named name.
+func tarWriteBytes(tw *tar.Writer, name string, b []byte) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(b))}); err != nil {
+		return err
+	}
+	_, err := tw.Write(b)
+	return err
+}
+
+// tarWriteFile writes the file at path into tw, using path as the archive entry name.
+func tarWriteFile(tw *tar.Writer, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return tarWriteBytes(tw, path, b)
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	testSetup()
+	initializeKVInstance(dataSourceName)
+
+	vr := "v1.2.3"
+	DefaultConfig = Config{Version: &vr}
+	if err := DefaultConfig.Set(); err != nil {
+		t.Errorf("Set error: %v", err)
+		return
+	}
+
+	backupDir := t.TempDir()
+	backupFile := filepath.Join(backupDir, "extra.txt")
+	if err := os.WriteFile(backupFile, []byte("backed up contents"), 0644); err != nil {
+		t.Errorf("WriteFile error: %v", err)
+		return
+	}
+	pattern := filepath.Join(backupDir, "*.txt")
+
+	var buf bytes.Buffer
+	if err := Backup(&buf, "testapp", []string{pattern}); err != nil {
+		t.Errorf("Backup error: %v", err)
+		return
+	}
+
+	if err := os.Remove(backupFile); err != nil {
+		t.Errorf("Remove error: %v", err)
+		return
+	}
+
+	if err := Restore(bytes.NewReader(buf.Bytes()), "testapp", true, []string{pattern}); err != nil {
+		t.Errorf("Restore error: %v", err)
+		return
+	}
+
+	rcnfg, err := Get()
+	if err != nil {
+		t.Errorf("Get error: %v", err)
+		return
+	}
+	if rcnfg.Version == nil || *rcnfg.Version != vr {
+		t.Errorf("Get after Restore did not produce correct data, rcnfg: %+v", rcnfg)
+		return
+	}
+
+	restored, err := os.ReadFile(backupFile)
+	if err != nil {
+		t.Errorf("ReadFile after Restore error: %v", err)
+		return
+	}
+	if string(restored) != "backed up contents" {
+		t.Errorf("restored file contents got:%q want:%q", string(restored), "backed up contents")
+		return
+	}
+}
+
+func TestRestoreRejectsAppNameMismatch(t *testing.T) {
+	testSetup()
+	initializeKVInstance(dataSourceName)
+
+	var buf bytes.Buffer
+	if err := Backup(&buf, "testapp", nil); err != nil {
+		t.Errorf("Backup error: %v", err)
+		return
+	}
+
+	if err := Restore(bytes.NewReader(buf.Bytes()), "otherapp", true, nil); err == nil {
+		t.Error("Restore did not return an error for a mismatched app name")
+		return
+	}
+}
+
+func TestRestoreRejectsIncompatibleSchemaVersion(t *testing.T) {
+	testSetup()
+	initializeKVInstance(dataSourceName)
+
+	var buf bytes.Buffer
+	if err := Backup(&buf, "testapp", nil); err != nil {
+		t.Errorf("Backup error: %v", err)
+		return
+	}
+
+	tampered := rewriteManifestSchemaVersion(t, buf.Bytes(), backupSchemaVersion+1)
+	if err := Restore(bytes.NewReader(tampered), "testapp", true, nil); err == nil {
+		t.Error("Restore did not return an error for an incompatible schema version")
+		return
+	}
+}
+
+func TestRestoreRejectsPathOutsideAllowedPatterns(t *testing.T) {
+	testSetup()
+	initializeKVInstance(dataSourceName)
+
+	dir := t.TempDir()
+	sneaky := filepath.Join(dir, "sneaky.txt")
+	if err := os.WriteFile(sneaky, []byte("should not be written back"), 0644); err != nil {
+		t.Errorf("WriteFile error: %v", err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := Backup(&buf, "testapp", []string{sneaky}); err != nil {
+		t.Errorf("Backup error: %v", err)
+		return
+	}
+	if err := os.Remove(sneaky); err != nil {
+		t.Errorf("Remove error: %v", err)
+		return
+	}
+
+	// Restore with a different pattern than the one Backup used: the archived file entry no
+	// longer matches any allowed pattern and must be rejected rather than written back.
+	if err := Restore(bytes.NewReader(buf.Bytes()), "testapp", true, []string{filepath.Join(dir, "other-*.txt")}); err == nil {
+		t.Error("Restore did not return an error for a path outside the allowed patterns")
+		return
+	}
+	if _, err := os.Stat(sneaky); !os.IsNotExist(err) {
+		t.Error("Restore wrote a file outside the allowed patterns")
+		return
+	}
+}
+
+// rewriteManifestSchemaVersion rewrites the manifest entry of a Backup archive to schemaVersion,
+// so tests can exercise Restore's rejection of an incompatible schema version.
+func rewriteManifestSchemaVersion(t *testing.T, archive []byte, schemaVersion int) []byte {
+	gzr, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Errorf("gzip.NewReader error: %v", err)
+		return nil
+	}
+	tr := tar.NewReader(gzr)
+
+	var out bytes.Buffer
+	gzw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gzw)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Errorf("reading entry %q error: %v", hdr.Name, err)
+			return nil
+		}
+		if hdr.Name == backupManifestName {
+			b = []byte(`{"schemaVersion":` + strconv.Itoa(schemaVersion) + `,"appName":"testapp","createdUnix":0}`)
+		}
+		hdr.Size = int64(len(b))
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Errorf("WriteHeader error: %v", err)
+			return nil
+		}
+		if _, err := tw.Write(b); err != nil {
+			t.Errorf("Write error: %v", err)
+			return nil
+		}
+	}
+	tw.Close()
+	gzw.Close()
+	return out.Bytes()
+}
@@ -18,11 +18,13 @@ import (
 
 type Config struct {
 	// CLI parameters
-	HTTPSPort   *int    `json:",omitempty"`
-	LogFilepath *string `json:",omitempty"`
-	LogLevel    *int    `json:",omitempty"`
+	HTTPSPort       *int           `json:",omitempty"`
+	LogFilepath     *string        `json:",omitempty"`
+	LogLevel        *int           `json:",omitempty"`
+	ShutdownTimeout *time.Duration `json:",omitempty"`
 
 	// Other
+	ACME                   *ACMEConfig    `json:",omitempty"`
 	AppName                *string        `json:",omitempty"`
 	AppPath                *string        `json:",omitempty"`
 	AuditLogName           *string        `json:",omitempty"`
@@ -46,6 +48,18 @@ var (
 	// during Init.
 	DefaultConfig Config
 	kvi           kvs.KVS
+
+	// appLogger and auditLogger are the Loggers internal config (and common) call sites log
+	// through. They default to LoghLogger, wrapping the logh loggers Init creates, unless Init
+	// is given a customLogger.
+	appLogger   Logger
+	auditLogger Logger
+
+	// appLogCheckSize and appLogMaxSize are the logh rotation parameters Init was given for the
+	// application log, kept so Watch can recreate that log, at a new level, on reload; see
+	// applyReload.
+	appLogCheckSize int
+	appLogMaxSize   int64
 )
 
 // flags for CLI
@@ -57,6 +71,8 @@ var (
 	persistentDirectory = flag.String("persistent-directory", "", "Fully qualified path to directory for persisted data; default to directory of this executable.")
 	reset               = flag.Bool("reset", false, "Reset will remove all persisted data for this instance; "+
 		"includes user accounts, settings, log files, etc.")
+	shutdownTimeout = flag.Duration("shutdown-timeout", 10*time.Second,
+		"Maximum time to wait for in-flight requests to drain during a graceful shutdown; see common.Run.")
 )
 
 // Init initializes the configuration and logging for the application.
@@ -65,16 +81,27 @@ var (
 // checkLogSizeAudit/maxLogSizeAudit - logh parameters for the audit log.
 // filepathsToDeleteOnReset - fully qualified file paths for any files that needs deleted on
 // application reset. Uses Glob patterns.
+// customLogger - Logger implementation internal config/common log through, in place of logh
+// directly; pass nil to use the default, LoghLogger.
 func Init(cnfg Config, checkLogSize int, maxLogSize int64,
-	checkLogSizeAudit int, maxLogSizeAudit int64, filepathsToDeleteOnReset []string) {
+	checkLogSizeAudit int, maxLogSizeAudit int64, filepathsToDeleteOnReset []string, customLogger Logger) {
 
 	var err error
-	flag.Parse()
 
 	if cnfg.AppName == nil || cnfg.AppPath == nil || cnfg.LogName == nil {
 		log.Fatalf("fatal: cnfg.AppName, cnf.AppPath, and cnfg.LogName are required to be non-nil.")
 	}
 
+	if cfp := earlyFlagValue(configFlagName, os.Args[1:]); cfp != "" {
+		if err := SetFlagsFromConfig(flag.CommandLine, *cnfg.AppName, cfp); err != nil {
+			log.Fatalf("fatal: %s SetFlagsFromConfig error: %v", runtimeh.SourceInfo(), err)
+		}
+		if err := applyConfigFileFields(&cnfg, cfp); err != nil {
+			log.Fatalf("fatal: %s applyConfigFileFields error: %v", runtimeh.SourceInfo(), err)
+		}
+	}
+	flag.Parse()
+
 	if *persistentDirectory == "" {
 		persistentDirectory = cnfg.AppPath
 	}
@@ -86,6 +113,9 @@ func Init(cnfg Config, checkLogSize int, maxLogSize int64,
 	}
 
 	dataSourceName := filepath.Join(*persistentDirectory, *cnfg.AppName+".db")
+	if cnfg.DataSourceName != nil {
+		dataSourceName = *cnfg.DataSourceName
+	}
 	newDataSource := false
 	if _, err := os.Stat(dataSourceName); os.IsNotExist(err) {
 		newDataSource = true
@@ -98,6 +128,7 @@ func Init(cnfg Config, checkLogSize int, maxLogSize int64,
 	}
 
 	// logging setup
+	appLogCheckSize, appLogMaxSize = checkLogSize, maxLogSize
 	err = logh.New(*cnfg.LogName, *logFilepath, logh.DefaultLevels, logh.LoghLevel(*logLevel),
 		logh.DefaultFlags, checkLogSize, maxLogSize)
 	if err != nil {
@@ -113,19 +144,52 @@ func Init(cnfg Config, checkLogSize int, maxLogSize int64,
 	if err != nil {
 		log.Fatalf("fatal: %s error creating audit log, error: %v", runtimeh.SourceInfo(), err)
 	}
-	logh.Map[*cnfg.LogName].Printf(logh.Info, "%s is starting....", *cnfg.LogName)
-	logh.Map[auditLogName].Printf(logh.Audit, "%s is starting....", *cnfg.LogName)
-	logh.Map[*cnfg.LogName].Printf(logh.Info, "logFilepath:%s", *logFilepath)
-	logh.Map[*cnfg.LogName].Printf(logh.Info, "auditLogFilepath:%s", auditLogFilepath)
+
+	appLogger, auditLogger = deriveLoggers(customLogger, *cnfg.LogName, auditLogName)
+
+	startLogger := appLogger.With("app", *cnfg.LogName, "event", "startup")
+	startLogger.Printf(Info, "%s is starting....", *cnfg.LogName)
+	auditLogger.With("app", *cnfg.LogName, "event", "startup").Printf(Audit, "%s is starting....", *cnfg.LogName)
+	startLogger.Printf(Info, "logFilepath:%s", *logFilepath)
+	startLogger.Printf(Info, "auditLogFilepath:%s", auditLogFilepath)
 
 	initializeKVInstance(dataSourceName)
 
+	if *restoreFilepath != "" {
+		f, err := os.Open(*restoreFilepath)
+		if err != nil {
+			log.Fatalf("fatal: %s opening restore file, error: %v", runtimeh.SourceInfo(), err)
+		}
+		err = Restore(f, *cnfg.AppName, *reset, filepathsToDeleteOnReset)
+		f.Close()
+		if err != nil {
+			log.Fatalf("fatal: %s Restore error: %v", runtimeh.SourceInfo(), err)
+		}
+		appLogger.Printf(Info, "restored configuration from:%s", *restoreFilepath)
+	}
+
+	if *backupFilepath != "" {
+		f, err := os.Create(*backupFilepath)
+		if err != nil {
+			log.Fatalf("fatal: %s creating backup file, error: %v", runtimeh.SourceInfo(), err)
+		}
+		err = Backup(f, *cnfg.AppName, filepathsToDeleteOnReset)
+		f.Close()
+		if err != nil {
+			log.Fatalf("fatal: %s Backup error: %v", runtimeh.SourceInfo(), err)
+		}
+		appLogger.Printf(Info, "wrote configuration backup to:%s", *backupFilepath)
+	}
+
+	configMu.Lock()
+	defer configMu.Unlock()
 	DefaultConfig = cnfg
 	// CLI
 	DefaultConfig.HTTPSPort = httpsPort
 	DefaultConfig.LogFilepath = logFilepath
 	DefaultConfig.LogLevel = logLevel
 	DefaultConfig.PersistentDirectory = persistentDirectory
+	DefaultConfig.ShutdownTimeout = shutdownTimeout
 	// Other
 	DefaultConfig.AppName = cnfg.AppName
 	DefaultConfig.AuditLogName = &auditLogName
@@ -149,12 +213,47 @@ func Delete() error {
 	return runtimeh.SourceInfoError("", kvi.DeleteStore())
 }
 
+// AppLogger returns the Logger used for application-level events: the customLogger given to
+// Init, or a LoghLogger wrapping the application log if Init was given none.
+func AppLogger() Logger {
+	return appLogger
+}
+
+// AuditLogger returns the Logger used for audit events: the customLogger given to Init, or an
+// AuditJSONLogger wrapping the audit log if Init was given none.
+func AuditLogger() Logger {
+	return auditLogger
+}
+
+// Close closes the KVS connection. Call this during graceful shutdown, after the HTTP server
+// has stopped accepting new requests, so any final Set calls have already landed.
+func Close() error {
+	return runtimeh.SourceInfoError("", kvi.Close())
+}
+
 // Get returns the current configuration. The current configuration is based on default/CLI values,
-// but those may be overriden by saved values.
+// but those may be overriden by saved values. String fields that reference other fields or
+// environment variables, via "{{.AppName}}" / "{{env \"HOME\"}}" syntax, are resolved; see
+// interpolateConfig.
 func Get() (Config, error) {
+	configMu.RLock()
 	mergedConfig := DefaultConfig
-	err := kvi.Deserialize(configKey, &mergedConfig)
-	return mergedConfig, runtimeh.SourceInfoError("", err)
+	// DefaultConfig is copied by value above, but PasswordValidation is a slice: the copy
+	// shares DefaultConfig's backing array until cloned here. Without this clone,
+	// interpolateConfig's in-place cnfg.PasswordValidation[i] = v writes below, which run after
+	// configMu is released, would race with Watch's applyReload mutating DefaultConfig under
+	// configMu.Lock (and with any other concurrent Get call reading the same array).
+	mergedConfig.PasswordValidation = append([]string(nil), mergedConfig.PasswordValidation...)
+	configMu.RUnlock()
+	if err := kvi.Deserialize(configKey, &mergedConfig); err != nil {
+		return mergedConfig, runtimeh.SourceInfoError("", err)
+	}
+
+	if err := interpolateConfig(&mergedConfig); err != nil {
+		return mergedConfig, runtimeh.SourceInfoError("", err)
+	}
+
+	return mergedConfig, nil
 }
 
 func checkReset(reset bool, dataSourceName string, filepathsToDeleteOnReset []string) error {
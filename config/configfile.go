@@ -0,0 +1,206 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/paulfdunn/osh/runtimeh"
+	"gopkg.in/yaml.v2"
+)
+
+// configFlagName is the name of the flag used to point at an optional config file.
+const configFlagName = "config"
+
+var (
+	configFilepath = flag.String(configFlagName, "", "Fully qualified path to a YAML, TOML, "+
+		"or JSON config file used to seed flag defaults; see SetFlagsFromConfig for precedence rules.")
+)
+
+// SetFlagsFromConfig seeds fs with values from configFilepath (a YAML, TOML, or JSON document,
+// format chosen by file extension) and then from environment variables named
+// appName_FLAGNAME (upper cased, with dashes replaced by underscores). It must be called prior
+// to flag.Parse so that any flag explicitly provided on the CLI retains the highest precedence.
+// Resulting precedence, low to high: built-in default < config file < environment variable < CLI flag.
+//
+// Keys in configFilepath that name neither a registered flag nor a fileOnlyConfigFields field
+// (applied separately; see applyConfigFileFields) are logged as unrecognized rather than
+// silently ignored, so a typo'd key doesn't fail silently.
+func SetFlagsFromConfig(fs *flag.FlagSet, appName string, configFilepath string) error {
+	values, err := configFileValues(configFilepath)
+	if err != nil {
+		return runtimeh.SourceInfoError("", err)
+	}
+
+	var errOut error
+	fs.VisitAll(func(f *flag.Flag) {
+		if v, ok := values[f.Name]; ok {
+			if err := fs.Set(f.Name, v); err != nil {
+				errOut = fmt.Errorf("setting flag %q from config file, error: %v, prior errors: %v", f.Name, err, errOut)
+			}
+		}
+
+		envName := envVarName(appName, f.Name)
+		if v, ok := os.LookupEnv(envName); ok {
+			if err := fs.Set(f.Name, v); err != nil {
+				errOut = fmt.Errorf("setting flag %q from environment variable %q, error: %v, prior errors: %v", f.Name, envName, err, errOut)
+			}
+		}
+	})
+
+	for k := range values {
+		if fs.Lookup(k) != nil || isFileOnlyConfigField(k) {
+			continue
+		}
+		log.Printf("config file %q: key %q does not match a known setting; ignored", configFilepath, k)
+	}
+
+	return runtimeh.SourceInfoError("", errOut)
+}
+
+// fileOnlyConfig is the subset of Config fields with no registered CLI flag that can still be
+// declared directly in --config, under their Config field name rather than a flag name.
+// SetFlagsFromConfig only seeds registered flags, so Init applies these separately, via
+// applyConfigFileFields, onto the Config it was given.
+type fileOnlyConfig struct {
+	PasswordValidation     []string       `json:"PasswordValidation,omitempty"`
+	JWTAuthTimeoutInterval *time.Duration `json:"JWTAuthTimeoutInterval,omitempty"`
+	JWTAuthRemoveInterval  *time.Duration `json:"JWTAuthRemoveInterval,omitempty"`
+	DataSourceName         *string        `json:"DataSourceName,omitempty"`
+}
+
+// fileOnlyConfigFields names fileOnlyConfig's fields, so SetFlagsFromConfig recognizes them as
+// handled, rather than warning about them as unrecognized config file keys.
+var fileOnlyConfigFields = []string{
+	"PasswordValidation", "JWTAuthTimeoutInterval", "JWTAuthRemoveInterval", "DataSourceName",
+}
+
+func isFileOnlyConfigField(name string) bool {
+	for _, f := range fileOnlyConfigFields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyConfigFileFields overlays any fileOnlyConfig fields declared in configFilepath onto cnfg.
+// This is the cold-start counterpart to Watch's later hot-reload of
+// JWTAuthTimeoutInterval/JWTAuthRemoveInterval/PasswordValidation (see reloadableConfig):
+// those, plus DataSourceName, have no registered CLI flag, so SetFlagsFromConfig cannot seed
+// them, and without this they would otherwise only take effect on the first config file change
+// after Watch starts, rather than at startup. An empty configFilepath is not an error; it simply
+// leaves cnfg unchanged.
+func applyConfigFileFields(cnfg *Config, configFilepath string) error {
+	if configFilepath == "" {
+		return nil
+	}
+
+	raw, err := rawConfigFile(configFilepath)
+	if err != nil {
+		return runtimeh.SourceInfoError("", err)
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return runtimeh.SourceInfoError("re-marshaling config file", err)
+	}
+	var fileOnly fileOnlyConfig
+	if err := json.Unmarshal(b, &fileOnly); err != nil {
+		return runtimeh.SourceInfoError("decoding config file", err)
+	}
+
+	if fileOnly.PasswordValidation != nil {
+		cnfg.PasswordValidation = fileOnly.PasswordValidation
+	}
+	if fileOnly.JWTAuthTimeoutInterval != nil {
+		cnfg.JWTAuthTimeoutInterval = fileOnly.JWTAuthTimeoutInterval
+	}
+	if fileOnly.JWTAuthRemoveInterval != nil {
+		cnfg.JWTAuthRemoveInterval = fileOnly.JWTAuthRemoveInterval
+	}
+	if fileOnly.DataSourceName != nil {
+		cnfg.DataSourceName = fileOnly.DataSourceName
+	}
+
+	return nil
+}
+
+// envVarName returns the environment variable name checked for a given flag name, e.g.
+// flag "https-port" for appName "myapp" becomes "MYAPP_HTTPS_PORT".
+func envVarName(appName string, flagName string) string {
+	return strings.ToUpper(appName) + "_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// configFileValues reads configFilepath and returns the flat key/value pairs it contains, with
+// values converted to strings suitable for flag.Flag.Value.Set. An empty configFilepath is not
+// an error; it simply yields no values.
+func configFileValues(configFilepath string) (map[string]string, error) {
+	values := map[string]string{}
+	if configFilepath == "" {
+		return values, nil
+	}
+
+	raw, err := rawConfigFile(configFilepath)
+	if err != nil {
+		return nil, runtimeh.SourceInfoError("", err)
+	}
+
+	for k, v := range raw {
+		values[k] = fmt.Sprintf("%v", v)
+	}
+	return values, nil
+}
+
+// rawConfigFile reads configFilepath, a YAML, TOML, or JSON document (format chosen by file
+// extension), into a generic key/value map. Callers that need typed fields, rather than the
+// flattened strings configFileValues produces, can re-marshal this map to JSON and unmarshal it
+// into a struct with `json` tags.
+func rawConfigFile(configFilepath string) (map[string]interface{}, error) {
+	b, err := os.ReadFile(configFilepath)
+	if err != nil {
+		return nil, runtimeh.SourceInfoError("reading config file", err)
+	}
+
+	raw := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(configFilepath)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &raw)
+	case ".toml":
+		err = toml.Unmarshal(b, &raw)
+	case ".json":
+		err = json.Unmarshal(b, &raw)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension: %q", filepath.Ext(configFilepath))
+	}
+	if err != nil {
+		return nil, runtimeh.SourceInfoError("parsing config file", err)
+	}
+
+	return raw, nil
+}
+
+// earlyFlagValue scans args for the value of a flag named name, in either "-name value",
+// "-name=value", "--name value", or "--name=value" form. It exists because configFilepath
+// must be known prior to flag.Parse being called, so the config file can seed flag defaults
+// before CLI flags are parsed on top of them.
+func earlyFlagValue(name string, args []string) string {
+	for i, a := range args {
+		trimmed := strings.TrimLeft(a, "-")
+		if trimmed == a {
+			continue
+		}
+		if trimmed == name && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(trimmed, name+"=") {
+			return strings.TrimPrefix(trimmed, name+"=")
+		}
+	}
+	return ""
+}
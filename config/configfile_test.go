@@ -0,0 +1,190 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEnvVarName(t *testing.T) {
+	if got, want := envVarName("myapp", "https-port"), "MYAPP_HTTPS_PORT"; got != want {
+		t.Errorf("envVarName got:%q want:%q", got, want)
+		return
+	}
+}
+
+func TestEarlyFlagValue(t *testing.T) {
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-config", "/tmp/a.yaml"}, "/tmp/a.yaml"},
+		{[]string{"--config", "/tmp/a.yaml"}, "/tmp/a.yaml"},
+		{[]string{"-config=/tmp/a.yaml"}, "/tmp/a.yaml"},
+		{[]string{"--config=/tmp/a.yaml"}, "/tmp/a.yaml"},
+		{[]string{"-other", "value"}, ""},
+		{[]string{}, ""},
+	}
+	for _, c := range cases {
+		if got := earlyFlagValue("config", c.args); got != c.want {
+			t.Errorf("earlyFlagValue(%v) got:%q want:%q", c.args, got, c.want)
+			return
+		}
+	}
+}
+
+func TestRawConfigFile(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlFile := filepath.Join(dir, "cnfg.yaml")
+	if err := os.WriteFile(yamlFile, []byte("https-port: \"9090\"\n"), 0644); err != nil {
+		t.Errorf("WriteFile error: %v", err)
+		return
+	}
+	tomlFile := filepath.Join(dir, "cnfg.toml")
+	if err := os.WriteFile(tomlFile, []byte("https-port = \"9090\"\n"), 0644); err != nil {
+		t.Errorf("WriteFile error: %v", err)
+		return
+	}
+	jsonFile := filepath.Join(dir, "cnfg.json")
+	if err := os.WriteFile(jsonFile, []byte(`{"https-port": "9090"}`), 0644); err != nil {
+		t.Errorf("WriteFile error: %v", err)
+		return
+	}
+
+	for _, f := range []string{yamlFile, tomlFile, jsonFile} {
+		raw, err := rawConfigFile(f)
+		if err != nil {
+			t.Errorf("rawConfigFile(%q) error: %v", f, err)
+			return
+		}
+		if raw["https-port"] != "9090" {
+			t.Errorf("rawConfigFile(%q) got:%v want https-port:9090", f, raw)
+			return
+		}
+	}
+
+	if _, err := rawConfigFile(filepath.Join(dir, "cnfg.ini")); err == nil {
+		t.Error("rawConfigFile did not return an error for an unsupported extension")
+		return
+	}
+}
+
+func TestSetFlagsFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	cnfgFile := filepath.Join(dir, "cnfg.json")
+	if err := os.WriteFile(cnfgFile, []byte(`{"test-flag-from-file": "fromfile"}`), 0644); err != nil {
+		t.Errorf("WriteFile error: %v", err)
+		return
+	}
+
+	envName := envVarName("testapp", "test-flag-from-env")
+	os.Setenv(envName, "fromenv")
+	defer os.Unsetenv(envName)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fromFile := fs.String("test-flag-from-file", "default", "")
+	fromEnv := fs.String("test-flag-from-env", "default", "")
+	untouched := fs.String("test-flag-untouched", "default", "")
+
+	if err := SetFlagsFromConfig(fs, "testapp", cnfgFile); err != nil {
+		t.Errorf("SetFlagsFromConfig error: %v", err)
+		return
+	}
+
+	if *fromFile != "fromfile" {
+		t.Errorf("test-flag-from-file got:%q want:%q", *fromFile, "fromfile")
+		return
+	}
+	if *fromEnv != "fromenv" {
+		t.Errorf("test-flag-from-env got:%q want:%q", *fromEnv, "fromenv")
+		return
+	}
+	if *untouched != "default" {
+		t.Errorf("test-flag-untouched got:%q want:%q", *untouched, "default")
+		return
+	}
+}
+
+// TestSetFlagsFromConfigWarnsOnUnrecognizedKey guards against silently dropping a typo'd config
+// file key: a key matching neither a registered flag nor a fileOnlyConfigFields field should not
+// cause an error (SetFlagsFromConfig only warns, via log.Printf), and recognized keys alongside
+// it must still be applied.
+func TestSetFlagsFromConfigWarnsOnUnrecognizedKey(t *testing.T) {
+	dir := t.TempDir()
+	cnfgFile := filepath.Join(dir, "cnfg.json")
+	if err := os.WriteFile(cnfgFile, []byte(`{"test-flag-from-file":"fromfile","typo-flag":"oops"}`), 0644); err != nil {
+		t.Errorf("WriteFile error: %v", err)
+		return
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fromFile := fs.String("test-flag-from-file", "default", "")
+
+	if err := SetFlagsFromConfig(fs, "testapp", cnfgFile); err != nil {
+		t.Errorf("SetFlagsFromConfig error: %v", err)
+		return
+	}
+	if *fromFile != "fromfile" {
+		t.Errorf("test-flag-from-file got:%q want:%q", *fromFile, "fromfile")
+		return
+	}
+}
+
+func TestApplyConfigFileFieldsEmptyPathIsNoop(t *testing.T) {
+	cnfg := Config{}
+	if err := applyConfigFileFields(&cnfg, ""); err != nil {
+		t.Errorf("applyConfigFileFields error: %v", err)
+		return
+	}
+	if cnfg.DataSourceName != nil || cnfg.PasswordValidation != nil {
+		t.Errorf("applyConfigFileFields modified cnfg with an empty path: %+v", cnfg)
+		return
+	}
+}
+
+func TestApplyConfigFileFieldsOverlaysFileOnlyFields(t *testing.T) {
+	dir := t.TempDir()
+	cnfgFile := filepath.Join(dir, "cnfg.json")
+	contents := `{
+		"PasswordValidation": ["^.{8,}$", "[0-9]"],
+		"JWTAuthTimeoutInterval": 900000000000,
+		"JWTAuthRemoveInterval": 3600000000000,
+		"DataSourceName": "/tmp/override.db"
+	}`
+	if err := os.WriteFile(cnfgFile, []byte(contents), 0644); err != nil {
+		t.Errorf("WriteFile error: %v", err)
+		return
+	}
+
+	name := "original"
+	cnfg := Config{AppName: &name}
+	if err := applyConfigFileFields(&cnfg, cnfgFile); err != nil {
+		t.Errorf("applyConfigFileFields error: %v", err)
+		return
+	}
+
+	if len(cnfg.PasswordValidation) != 2 || cnfg.PasswordValidation[1] != "[0-9]" {
+		t.Errorf("PasswordValidation got:%v", cnfg.PasswordValidation)
+		return
+	}
+	if cnfg.JWTAuthTimeoutInterval == nil || *cnfg.JWTAuthTimeoutInterval != 15*time.Minute {
+		t.Errorf("JWTAuthTimeoutInterval got:%v want:15m", cnfg.JWTAuthTimeoutInterval)
+		return
+	}
+	if cnfg.JWTAuthRemoveInterval == nil || *cnfg.JWTAuthRemoveInterval != time.Hour {
+		t.Errorf("JWTAuthRemoveInterval got:%v want:1h", cnfg.JWTAuthRemoveInterval)
+		return
+	}
+	if cnfg.DataSourceName == nil || *cnfg.DataSourceName != "/tmp/override.db" {
+		t.Errorf("DataSourceName got:%v want:/tmp/override.db", cnfg.DataSourceName)
+		return
+	}
+	// AppName was not present in the config file, so it must be left untouched.
+	if *cnfg.AppName != "original" {
+		t.Errorf("AppName got:%q want:%q (unchanged)", *cnfg.AppName, "original")
+		return
+	}
+}
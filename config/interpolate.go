@@ -0,0 +1,102 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// maxInterpolatedFieldLen caps the size of any one field after expansion, so a pathological
+// template (e.g. one that repeats a long env var many times) can't grow a field unbounded.
+const maxInterpolatedFieldLen = 4 * 1024
+
+// interpolationSource holds the Config fields that template references inside other string
+// fields may resolve against. These fields are never themselves expanded: resolution is
+// exactly one pass against this fixed whitelist, so a template can't reference another
+// templated field, which rules out circular or billion-laughs style expansion.
+type interpolationSource struct {
+	AppName             string
+	AppPath             string
+	LogName             string
+	PersistentDirectory string
+}
+
+// interpolationFuncs are the template functions available to Config string fields, in addition
+// to the whitelisted fields on interpolationSource.
+var interpolationFuncs = template.FuncMap{
+	"env": os.Getenv,
+}
+
+// interpolateConfig resolves "{{.AppName}}" / "{{env \"HOME\"}}" style references within
+// cnfg.LogFilepath, cnfg.DataSourceName, and cnfg.PasswordValidation, against the other,
+// non-templated fields of cnfg. It is called once, from Get, after the saved/default merge.
+//
+// cnfg.PersistentDirectory is a source field, not a destination: its raw, literal value is
+// already consumed by Init (MkdirAll, the default DataSourceName) before Get is ever called, so
+// resolving templates inside it here would have no effect on the directory the application
+// actually uses. It is not interpolated.
+func interpolateConfig(cnfg *Config) error {
+	src := interpolationSource{}
+	if cnfg.AppName != nil {
+		src.AppName = *cnfg.AppName
+	}
+	if cnfg.AppPath != nil {
+		src.AppPath = *cnfg.AppPath
+	}
+	if cnfg.LogName != nil {
+		src.LogName = *cnfg.LogName
+	}
+	if cnfg.PersistentDirectory != nil {
+		src.PersistentDirectory = *cnfg.PersistentDirectory
+	}
+
+	if cnfg.LogFilepath != nil {
+		v, err := interpolate(*cnfg.LogFilepath, src)
+		if err != nil {
+			return fmt.Errorf("LogFilepath: %w", err)
+		}
+		cnfg.LogFilepath = &v
+	}
+	if cnfg.DataSourceName != nil {
+		v, err := interpolate(*cnfg.DataSourceName, src)
+		if err != nil {
+			return fmt.Errorf("DataSourceName: %w", err)
+		}
+		cnfg.DataSourceName = &v
+	}
+	for i, pv := range cnfg.PasswordValidation {
+		v, err := interpolate(pv, src)
+		if err != nil {
+			return fmt.Errorf("PasswordValidation[%d]: %w", i, err)
+		}
+		cnfg.PasswordValidation[i] = v
+	}
+
+	return nil
+}
+
+// interpolate resolves template references in s against src. s is returned unchanged if it
+// contains no "{{", so untemplated values incur no overhead and are never reinterpreted as
+// templates.
+func interpolate(s string, src interpolationSource) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	tmpl, err := template.New("config").Option("missingkey=error").Funcs(interpolationFuncs).Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("parsing template %q, error: %w", s, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, src); err != nil {
+		return "", fmt.Errorf("resolving template %q, error: %w", s, err)
+	}
+	if buf.Len() > maxInterpolatedFieldLen {
+		return "", fmt.Errorf("template %q expanded beyond %d bytes", s, maxInterpolatedFieldLen)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	src := interpolationSource{AppName: "myapp", PersistentDirectory: "/var/lib/myapp"}
+
+	v, err := interpolate("{{.PersistentDirectory}}/{{.AppName}}.log", src)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if v != "/var/lib/myapp/myapp.log" {
+		t.Errorf("unexpected result: %s", v)
+		return
+	}
+
+	// Values with no template syntax are returned unchanged.
+	v, err = interpolate("/var/lib/myapp/myapp.log", src)
+	if err != nil || v != "/var/lib/myapp/myapp.log" {
+		t.Errorf("unexpected result: %s, error: %v", v, err)
+		return
+	}
+
+	os.Setenv("REST_APP_COMMON_TEST_ENV", "fromEnv")
+	defer os.Unsetenv("REST_APP_COMMON_TEST_ENV")
+	v, err = interpolate(`{{env "REST_APP_COMMON_TEST_ENV"}}`, src)
+	if err != nil || v != "fromEnv" {
+		t.Errorf("unexpected result: %s, error: %v", v, err)
+		return
+	}
+
+	// Referencing a field outside the whitelist is an error, not a silent empty expansion.
+	_, err = interpolate("{{.NotAField}}", src)
+	if err == nil {
+		t.Error("expected error referencing an unresolved field")
+		return
+	}
+
+	long := "{{.AppName}}" + strings.Repeat("x", maxInterpolatedFieldLen)
+	_, err = interpolate(long, src)
+	if err == nil {
+		t.Error("expected error for a field expanding beyond maxInterpolatedFieldLen")
+		return
+	}
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/paulfdunn/logh"
+)
+
+// Level mirrors logh.LoghLevel, so callers implementing Logger don't need to import logh
+// themselves. LoghLogger converts back to logh.LoghLevel at the boundary.
+type Level int
+
+// Levels, matching logh.DefaultLevels.
+const (
+	Debug Level = iota
+	Info
+	Warning
+	Audit
+	Error
+)
+
+// Logger is the structured logging interface config and common log through internally,
+// instead of calling logh.Map directly. The default implementation, LoghLogger, wraps logh;
+// callers that prefer klog, zap, or logrus can implement Logger themselves and pass it to
+// ConfigInit.
+type Logger interface {
+	// Printf logs format/v, in the style of logh.Logger.Printf. Key/value pairs attached via
+	// With are incorporated according to the implementation.
+	Printf(level Level, format string, v ...interface{})
+	// With returns a Logger that incorporates kv (alternating key, value, key, value, ...)
+	// into every subsequent Printf call.
+	With(kv ...interface{}) Logger
+}
+
+// LoghLogger is the default Logger; it wraps a named logh.Logger, appending any With key/value
+// pairs as "key=value" suffixes so the underlying log line stays human-readable.
+type LoghLogger struct {
+	name string
+	kv   []interface{}
+}
+
+// NewLoghLogger returns a Logger backed by logh.Map[name].
+func NewLoghLogger(name string) *LoghLogger {
+	return &LoghLogger{name: name}
+}
+
+func (l *LoghLogger) Printf(level Level, format string, v ...interface{}) {
+	msg := fmt.Sprintf(format, v...)
+	if len(l.kv) > 0 {
+		msg = msg + "| " + kvString(l.kv)
+	}
+	logh.Map[l.name].Printf(logh.LoghLevel(level), "%s", msg)
+}
+
+func (l *LoghLogger) With(kv ...interface{}) Logger {
+	return &LoghLogger{name: l.name, kv: appendKV(l.kv, kv)}
+}
+
+// AuditJSONLogger is a Logger that emits each call as a single JSON line of its message plus
+// any With key/value pairs, so downstream log pipelines (Loki, ELK, ...) can index the fields
+// directly. It still writes through logh.Map[name], so rotation and level filtering behave the
+// same as for LoghLogger.
+type AuditJSONLogger struct {
+	name string
+	kv   []interface{}
+}
+
+// NewAuditJSONLogger returns a Logger backed by logh.Map[name] that emits JSON lines.
+func NewAuditJSONLogger(name string) *AuditJSONLogger {
+	return &AuditJSONLogger{name: name}
+}
+
+func (l *AuditJSONLogger) Printf(level Level, format string, v ...interface{}) {
+	fields := map[string]interface{}{"msg": fmt.Sprintf(format, v...)}
+	for i := 0; i+1 < len(l.kv); i += 2 {
+		if k, ok := l.kv[i].(string); ok {
+			fields[k] = l.kv[i+1]
+		}
+	}
+	b, err := json.Marshal(fields)
+	if err != nil {
+		logh.Map[l.name].Printf(logh.Error, "AuditJSONLogger: marshaling fields, error: %v", err)
+		return
+	}
+	logh.Map[l.name].Println(logh.LoghLevel(level), string(b))
+}
+
+func (l *AuditJSONLogger) With(kv ...interface{}) Logger {
+	return &AuditJSONLogger{name: l.name, kv: appendKV(l.kv, kv)}
+}
+
+// deriveLoggers returns the app and audit Loggers Init installs. With customLogger nil, app
+// logs through a LoghLogger and audit through an AuditJSONLogger, both wrapping the logh loggers
+// Init created, one per logName; audit defaults to JSON since audit trails are more often
+// consumed by downstream log pipelines than read directly. With a non-nil customLogger, handing
+// that same object to both would collapse the app and audit streams into one sink, so each is
+// instead derived via With, giving the caller's Logger a "log":"app"/"log":"audit" key to route
+// or label on, while remaining independently addressable.
+func deriveLoggers(customLogger Logger, logName string, auditLogName string) (app Logger, audit Logger) {
+	if customLogger == nil {
+		return NewLoghLogger(logName), NewAuditJSONLogger(auditLogName)
+	}
+	return customLogger.With("log", "app"), customLogger.With("log", "audit")
+}
+
+// appendKV returns a new slice combining base and additional, so With never mutates a shared
+// backing array across derived Loggers.
+func appendKV(base []interface{}, additional []interface{}) []interface{} {
+	combined := make([]interface{}, 0, len(base)+len(additional))
+	combined = append(combined, base...)
+	combined = append(combined, additional...)
+	return combined
+}
+
+// kvString renders kv (alternating key, value, ...) as "key=value key=value ...".
+func kvString(kv []interface{}) string {
+	parts := make([]string, 0, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		parts = append(parts, fmt.Sprintf("%v=%v", kv[i], kv[i+1]))
+	}
+	return strings.Join(parts, " ")
+}
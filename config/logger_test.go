@@ -0,0 +1,153 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/paulfdunn/logh"
+)
+
+func TestKVString(t *testing.T) {
+	got := kvString([]interface{}{"app", "myapp", "event", "startup"})
+	want := "app=myapp event=startup"
+	if got != want {
+		t.Errorf("kvString got:%q want:%q", got, want)
+		return
+	}
+
+	if got := kvString(nil); got != "" {
+		t.Errorf("kvString of nil got:%q want empty string", got)
+		return
+	}
+}
+
+func TestAppendKV(t *testing.T) {
+	base := []interface{}{"app", "myapp"}
+	combined := appendKV(base, []interface{}{"event", "shutdown"})
+
+	want := "app=myapp event=shutdown"
+	if got := kvString(combined); got != want {
+		t.Errorf("appendKV got:%q want:%q", got, want)
+		return
+	}
+	if len(base) != 2 {
+		t.Errorf("appendKV mutated base, len:%d want:2", len(base))
+		return
+	}
+}
+
+// fakeLogger is a minimal Logger used to verify deriveLoggers' With-based tagging without
+// depending on LoghLogger's own With implementation.
+type fakeLogger struct {
+	kv []interface{}
+}
+
+func (f *fakeLogger) Printf(level Level, format string, v ...interface{}) {}
+
+func (f *fakeLogger) With(kv ...interface{}) Logger {
+	return &fakeLogger{kv: appendKV(f.kv, kv)}
+}
+
+func TestDeriveLoggersNilCustomDefaultsAppLoghAuditJSON(t *testing.T) {
+	app, audit := deriveLoggers(nil, "app-log", "audit-log")
+
+	if _, ok := app.(*LoghLogger); !ok {
+		t.Errorf("app got:%T want:*LoghLogger", app)
+		return
+	}
+	if _, ok := audit.(*AuditJSONLogger); !ok {
+		t.Errorf("audit got:%T want:*AuditJSONLogger", audit)
+		return
+	}
+}
+
+// TestDeriveLoggersCustomLoggerAreDistinctAndTagged guards against the bug where Init handed the
+// same customLogger object to both appLogger and auditLogger, collapsing the two streams: each
+// returned Logger must be its own value, tagged so a caller's backend can route or label them
+// independently.
+func TestDeriveLoggersCustomLoggerAreDistinctAndTagged(t *testing.T) {
+	app, audit := deriveLoggers(&fakeLogger{}, "app-log", "audit-log")
+
+	appFake, ok := app.(*fakeLogger)
+	if !ok {
+		t.Errorf("app got:%T want:*fakeLogger", app)
+		return
+	}
+	auditFake, ok := audit.(*fakeLogger)
+	if !ok {
+		t.Errorf("audit got:%T want:*fakeLogger", audit)
+		return
+	}
+	if appFake == auditFake {
+		t.Error("app and audit Loggers are the same object; customLogger was handed to both unchanged")
+		return
+	}
+	if got, want := kvString(appFake.kv), "log=app"; got != want {
+		t.Errorf("app tag got:%q want:%q", got, want)
+		return
+	}
+	if got, want := kvString(auditFake.kv), "log=audit"; got != want {
+		t.Errorf("audit tag got:%q want:%q", got, want)
+		return
+	}
+}
+
+func TestAuditJSONLoggerPrintfEmitsParsableJSON(t *testing.T) {
+	dir := t.TempDir()
+	name := "audit-json-test"
+	logFile := filepath.Join(dir, "audit.log")
+	if err := logh.New(name, logFile, logh.DefaultLevels, logh.Audit, logh.DefaultFlags, 1, 1<<20); err != nil {
+		t.Errorf("logh.New error: %v", err)
+		return
+	}
+	defer logh.Map[name].Shutdown()
+
+	NewAuditJSONLogger(name).With("app", "myapp").Printf(Audit, "hello %s", "world")
+
+	b, err := os.ReadFile(logFile + ".0")
+	if err != nil {
+		t.Errorf("ReadFile error: %v", err)
+		return
+	}
+
+	i := strings.IndexByte(string(b), '{')
+	if i < 0 {
+		t.Errorf("no JSON found in log line: %q", string(b))
+		return
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b[i:], &fields); err != nil {
+		t.Errorf("Unmarshal error: %v, line: %q", err, string(b))
+		return
+	}
+	if fields["msg"] != "hello world" {
+		t.Errorf("fields[msg] got:%v want:%q", fields["msg"], "hello world")
+		return
+	}
+	if fields["app"] != "myapp" {
+		t.Errorf("fields[app] got:%v want:%q", fields["app"], "myapp")
+		return
+	}
+}
+
+func TestLoggerWithIsImmutable(t *testing.T) {
+	base := NewLoghLogger("test")
+	withApp := base.With("app", "myapp")
+	withEvent := withApp.With("event", "shutdown")
+
+	if got := kvString(base.kv); got != "" {
+		t.Errorf("base.kv mutated, got:%q want empty string", got)
+		return
+	}
+	if got, want := kvString(withApp.(*LoghLogger).kv), "app=myapp"; got != want {
+		t.Errorf("withApp.kv got:%q want:%q", got, want)
+		return
+	}
+	if got, want := kvString(withEvent.(*LoghLogger).kv), "app=myapp event=shutdown"; got != want {
+		t.Errorf("withEvent.kv got:%q want:%q", got, want)
+		return
+	}
+}
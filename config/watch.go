@@ -0,0 +1,200 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/paulfdunn/logh"
+	"github.com/paulfdunn/osh/runtimeh"
+)
+
+// watchDebounce coalesces the burst of write events many editors generate for a single
+// logical save (write-then-rename, temp-file-then-rename, etc.) into one reload.
+const watchDebounce = 200 * time.Millisecond
+
+// OnChangeFunc is called, with the previous and newly merged Config, any time Watch applies a
+// reloaded configuration. Funcs are invoked synchronously, in registration order, and should
+// not block.
+type OnChangeFunc func(old Config, new Config)
+
+// reloadableConfig is the subset of Config that Watch will re-read from the config file and
+// apply to DefaultConfig without requiring a restart.
+type reloadableConfig struct {
+	LogLevel               *int           `json:"log-level,omitempty"`
+	JWTAuthTimeoutInterval *time.Duration `json:"JWTAuthTimeoutInterval,omitempty"`
+	JWTAuthRemoveInterval  *time.Duration `json:"JWTAuthRemoveInterval,omitempty"`
+	PasswordValidation     []string       `json:"PasswordValidation,omitempty"`
+
+	// Present only so Watch can detect and warn about changes that require a restart.
+	HTTPSPort           *int    `json:"https-port,omitempty"`
+	PersistentDirectory *string `json:"persistent-directory,omitempty"`
+}
+
+var (
+	configMu      sync.RWMutex
+	onChangeFuncs []OnChangeFunc
+)
+
+// OnChange registers fn to be called whenever Watch applies a reloaded configuration.
+func OnChange(fn OnChangeFunc) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	onChangeFuncs = append(onChangeFuncs, fn)
+}
+
+// Watch starts an fsnotify watch on the --config file and, on write events, re-merges it into
+// DefaultConfig. It blocks until ctx is done, so callers should run it in its own goroutine.
+// Watch is a no-op, returning nil immediately, if Init was not given a --config file.
+//
+// LogLevel, JWTAuthTimeoutInterval, JWTAuthRemoveInterval, and PasswordValidation are applied to
+// DefaultConfig immediately. Of those, only LogLevel has an observable runtime effect on its
+// own, and only when Init was not given a customLogger: applyReload recreates the logh
+// application log at the new level. logh has no API to change an existing Logger's level in
+// place, so this is a Shutdown+New, same as Init's original setup. JWTAuthTimeoutInterval,
+// JWTAuthRemoveInterval, and PasswordValidation have no such effect here; authJWT keeps its own
+// Config, set once in authJWT.Init, and exposes no way to update it after the fact. Callers that
+// need those values to take effect without a restart must re-derive and apply them themselves
+// from an OnChange subscriber. HTTPSPort and PersistentDirectory cannot be changed without
+// restarting the application, so a change to either is logged as a warning and otherwise
+// ignored.
+func Watch(ctx context.Context) error {
+	if *configFilepath == "" {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return runtimeh.SourceInfoError("creating fsnotify watcher", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(*configFilepath); err != nil {
+		return runtimeh.SourceInfoError("watching config file", err)
+	}
+
+	var debounce *time.Timer
+	reload := make(chan struct{}, 1)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if debounce == nil {
+				debounce = time.AfterFunc(watchDebounce, func() {
+					select {
+					case reload <- struct{}{}:
+					default:
+					}
+				})
+			} else {
+				debounce.Reset(watchDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			appLogger.Printf(Error, "config watch error: %v", err)
+		case <-reload:
+			applyReload()
+		}
+	}
+}
+
+// applyReload re-reads the config file and merges any hot-reloadable fields into DefaultConfig,
+// then notifies subscribers with the prior and new snapshots.
+func applyReload() {
+	raw, err := rawConfigFile(*configFilepath)
+	if err != nil {
+		appLogger.Printf(Error, "config reload: reading config file, error: %v", err)
+		return
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		appLogger.Printf(Error, "config reload: re-marshaling config file, error: %v", err)
+		return
+	}
+	var reloaded reloadableConfig
+	if err := json.Unmarshal(b, &reloaded); err != nil {
+		appLogger.Printf(Error, "config reload: decoding config file, error: %v", err)
+		return
+	}
+
+	configMu.Lock()
+	old := DefaultConfig
+	levelChanged := reloaded.LogLevel != nil && (old.LogLevel == nil || *reloaded.LogLevel != *old.LogLevel)
+	if reloaded.LogLevel != nil {
+		DefaultConfig.LogLevel = reloaded.LogLevel
+	}
+	if reloaded.JWTAuthTimeoutInterval != nil {
+		DefaultConfig.JWTAuthTimeoutInterval = reloaded.JWTAuthTimeoutInterval
+	}
+	if reloaded.JWTAuthRemoveInterval != nil {
+		DefaultConfig.JWTAuthRemoveInterval = reloaded.JWTAuthRemoveInterval
+	}
+	if reloaded.PasswordValidation != nil {
+		DefaultConfig.PasswordValidation = reloaded.PasswordValidation
+	}
+	updated := DefaultConfig
+	configMu.Unlock()
+
+	if levelChanged {
+		applyLogLevel(updated)
+	}
+
+	if reloaded.HTTPSPort != nil && (old.HTTPSPort == nil || *reloaded.HTTPSPort != *old.HTTPSPort) {
+		appLogger.Printf(Warning,
+			"config reload: https-port changed in config file but requires a restart to take effect")
+	}
+	if reloaded.PersistentDirectory != nil && (old.PersistentDirectory == nil || *reloaded.PersistentDirectory != *old.PersistentDirectory) {
+		appLogger.Printf(Warning,
+			"config reload: persistent-directory changed in config file but requires a restart to take effect")
+	}
+
+	for _, fn := range onChangeFuncs {
+		fn(old, updated)
+	}
+}
+
+// applyLogLevel recreates the logh application log at cnfg.LogLevel, so the live logging level
+// actually changes rather than just the stored value. logh has no in-place level setter, so this
+// mirrors Init's original logh.New call with the new level, reusing the rotation parameters Init
+// was given. If Init was given a customLogger, appLogger is not a *LoghLogger and this is a
+// no-op: there is no generic way to push a level change into an arbitrary Logger implementation,
+// so a customLogger must react to its own OnChange subscription instead.
+func applyLogLevel(cnfg Config) {
+	if _, ok := appLogger.(*LoghLogger); !ok {
+		return
+	}
+	if cnfg.LogName == nil || cnfg.LogLevel == nil {
+		return
+	}
+
+	// Shutdown nils out the logh Logger's internal *log.Logger handles without removing it from
+	// logh.Map, so appLogger must not be used to report a failure here: it would call into the
+	// now half-shut-down logger. The standard logger is the same fallback Init itself uses for
+	// logging setup failures.
+	if err := logh.Map[*cnfg.LogName].Shutdown(); err != nil {
+		log.Printf("config reload: shutting down log for level change, error: %v", err)
+		return
+	}
+	logFilepathValue := ""
+	if cnfg.LogFilepath != nil {
+		logFilepathValue = *cnfg.LogFilepath
+	}
+	if err := logh.New(*cnfg.LogName, logFilepathValue, logh.DefaultLevels, logh.LoghLevel(*cnfg.LogLevel),
+		logh.DefaultFlags, appLogCheckSize, appLogMaxSize); err != nil {
+		log.Printf("config reload: recreating log at new level, error: %v", err)
+		return
+	}
+	appLogger.Printf(Info, "config reload: log-level changed to:%d", *cnfg.LogLevel)
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func init() {
+	// applyReload and applyLogLevel log through appLogger; outside of Init (which these tests
+	// don't call), it is nil. logh.Logger.Printf is nil-receiver safe, so an unregistered name
+	// is enough to exercise these paths without a real log file.
+	appLogger = NewLoghLogger("watch_test")
+}
+
+func TestApplyReloadMergesHotFieldsAndNotifiesOnChange(t *testing.T) {
+	testSetup()
+	initializeKVInstance(dataSourceName)
+
+	dir := t.TempDir()
+	cnfgFile := filepath.Join(dir, "cnfg.json")
+	if err := os.WriteFile(cnfgFile, []byte(`{"log-level": 2, "PasswordValidation": ["^.{8,}$"]}`), 0644); err != nil {
+		t.Errorf("WriteFile error: %v", err)
+		return
+	}
+	cnfgFilepath := cnfgFile
+	configFilepath = &cnfgFilepath
+
+	ll := 0
+	DefaultConfig = Config{LogLevel: &ll}
+
+	var gotOld, gotUpdated Config
+	called := 0
+	onChangeFuncs = nil
+	OnChange(func(old Config, updated Config) {
+		called++
+		gotOld = old
+		gotUpdated = updated
+	})
+	defer func() { onChangeFuncs = nil }()
+
+	applyReload()
+
+	if called != 1 {
+		t.Errorf("OnChange called %d times, want 1", called)
+		return
+	}
+	if *gotOld.LogLevel != 0 {
+		t.Errorf("old.LogLevel got:%d want:0", *gotOld.LogLevel)
+		return
+	}
+	if gotUpdated.LogLevel == nil || *gotUpdated.LogLevel != 2 {
+		t.Errorf("updated.LogLevel got:%v want:2", gotUpdated.LogLevel)
+		return
+	}
+	if len(gotUpdated.PasswordValidation) != 1 || gotUpdated.PasswordValidation[0] != "^.{8,}$" {
+		t.Errorf("updated.PasswordValidation got:%v", gotUpdated.PasswordValidation)
+		return
+	}
+	if DefaultConfig.LogLevel == nil || *DefaultConfig.LogLevel != 2 {
+		t.Errorf("DefaultConfig.LogLevel not applied, got:%v", DefaultConfig.LogLevel)
+		return
+	}
+}
+
+func TestApplyReloadRestartRequiredFieldsNotApplied(t *testing.T) {
+	testSetup()
+	initializeKVInstance(dataSourceName)
+
+	dir := t.TempDir()
+	cnfgFile := filepath.Join(dir, "cnfg.json")
+	if err := os.WriteFile(cnfgFile, []byte(`{"https-port": 9999}`), 0644); err != nil {
+		t.Errorf("WriteFile error: %v", err)
+		return
+	}
+	cnfgFilepath := cnfgFile
+	configFilepath = &cnfgFilepath
+
+	hp := 8080
+	DefaultConfig = Config{HTTPSPort: &hp}
+	onChangeFuncs = nil
+	defer func() { onChangeFuncs = nil }()
+
+	applyReload()
+
+	// HTTPSPort requires a restart: applyReload should log a warning (exercised, not asserted
+	// on here) but must leave DefaultConfig.HTTPSPort untouched.
+	if *DefaultConfig.HTTPSPort != 8080 {
+		t.Errorf("DefaultConfig.HTTPSPort got:%d want:8080 (unchanged)", *DefaultConfig.HTTPSPort)
+		return
+	}
+}
+
+// TestGetPasswordValidationIsIndependentOfDefaultConfig guards against the data race where
+// Get's returned Config shared PasswordValidation's backing array with DefaultConfig:
+// interpolateConfig's in-place writes on the returned Config must not be visible through
+// DefaultConfig, since Watch's applyReload mutates DefaultConfig under configMu.Lock while Get
+// holds no lock while interpolating.
+func TestGetPasswordValidationIsIndependentOfDefaultConfig(t *testing.T) {
+	testSetup()
+	initializeKVInstance(dataSourceName)
+
+	DefaultConfig = Config{PasswordValidation: []string{"original"}}
+
+	got, err := Get()
+	if err != nil {
+		t.Errorf("Get error: %v", err)
+		return
+	}
+	if len(got.PasswordValidation) != 1 || got.PasswordValidation[0] != "original" {
+		t.Errorf("Get().PasswordValidation got:%v", got.PasswordValidation)
+		return
+	}
+
+	got.PasswordValidation[0] = "mutated"
+	if DefaultConfig.PasswordValidation[0] != "original" {
+		t.Errorf("mutating Get's result changed DefaultConfig.PasswordValidation, got:%v", DefaultConfig.PasswordValidation)
+		return
+	}
+}
+
+func TestApplyLogLevelNoopForCustomLogger(t *testing.T) {
+	prevAppLogger := appLogger
+	defer func() { appLogger = prevAppLogger }()
+	appLogger = NewAuditJSONLogger("test-custom")
+
+	ll := int(Debug)
+	name := "test-custom"
+	// Must not panic, and must not attempt to touch logh.Map since appLogger is not *LoghLogger.
+	applyLogLevel(Config{LogName: &name, LogLevel: &ll})
+}
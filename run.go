@@ -0,0 +1,83 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/paulfdunn/logh"
+	"github.com/paulfdunn/rest-app-common/config"
+)
+
+// defaultShutdownTimeout bounds server.Shutdown when cnfg.ShutdownTimeout is nil.
+const defaultShutdownTimeout = 10 * time.Second
+
+// Run IS A BLOCKING FUNCTION that starts the HTTP server, as ListenAndServeTLS does, and
+// additionally waits for SIGINT or SIGTERM. On receipt of either, it stops accepting new
+// connections and drains in-flight requests via server.Shutdown (and, if ACME's HTTP-01
+// challenge listener is running, challengeServer.Shutdown alongside it), bounded by
+// cnfg.ShutdownTimeout (default defaultShutdownTimeout), then flushes the logh logs and closes
+// the config package's KVS via config.Close. Use this, rather than ListenAndServeTLS directly,
+// when running under systemd or a container orchestrator that sends SIGTERM and expects a
+// clean exit. The returned value is an exit code suitable for passing to os.Exit.
+func Run(cnfg config.Config, mux *http.ServeMux, port string, readTimeout time.Duration, writeTimeout time.Duration,
+	certFilepath string, keyFilepath string) int {
+	server := newHTTPServer(mux, port, readTimeout, writeTimeout)
+	challengeServer := prepareTLS(cnfg, server, mux, certFilepath, keyFilepath)
+
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		serveTLS(cnfg, server, certFilepath, keyFilepath)
+	}()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	<-sig
+
+	config.AuditLogger().With("app", *cnfg.LogName, "event", "shutdown").Printf(config.Audit,
+		"%s is shutting down....", *cnfg.LogName)
+
+	shutdownTimeout := defaultShutdownTimeout
+	if cnfg.ShutdownTimeout != nil {
+		shutdownTimeout = *cnfg.ShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// challengeServer and server share one shutdownTimeout deadline; shutting them down
+	// concurrently, rather than one after the other, keeps a slow-draining challengeServer from
+	// eating into the budget server needs to drain in-flight application requests.
+	exitCode := 0
+	var challengeShutdownErr error
+	challengeShutdownDone := make(chan struct{})
+	go func() {
+		defer close(challengeShutdownDone)
+		if challengeServer != nil {
+			challengeShutdownErr = challengeServer.Shutdown(ctx)
+		}
+	}()
+	if err := server.Shutdown(ctx); err != nil {
+		config.AppLogger().Printf(config.Error, "server.Shutdown error: %v", err)
+		exitCode = 1
+	}
+	<-challengeShutdownDone
+	if challengeShutdownErr != nil {
+		config.AppLogger().Printf(config.Error, "challengeServer.Shutdown error: %v", challengeShutdownErr)
+		exitCode = 1
+	}
+	<-served
+
+	if err := config.Close(); err != nil {
+		config.AppLogger().Printf(config.Error, "config.Close error: %v", err)
+		exitCode = 1
+	}
+	if err := logh.ShutdownAll(); err != nil {
+		exitCode = 1
+	}
+
+	return exitCode
+}